@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// rangeEntry is one row of a prefix-range specification, either supplied
+// directly on the command line or loaded from a --file CSV export.
+type rangeEntry struct {
+	Code     string
+	From     string
+	To       string
+	Operator string
+	Region   string
+}
+
+// digitSpan renders the inclusive digit range [lo, hi] the way Asterisk
+// dialplan patterns expect: a literal digit when the span is a single
+// value, "X" when it covers the full 0-9 block, and a "[lo-hi]" digit
+// class for anything in between.
+func digitSpan(lo, hi byte) string {
+	switch {
+	case lo == hi:
+		return string(lo)
+	case lo == '0' && hi == '9':
+		return "X"
+	default:
+		return "[" + string(lo) + "-" + string(hi) + "]"
+	}
+}
+
+// splitNumericRange splits the inclusive range [min, max] of equal-length
+// decimal digit strings into the minimal set of dialplan-style suffixes:
+// exact digits where the range is pinned down, "X" for digit positions that
+// span a full 0-9 block, and "[a-b]" digit classes for partial spans at the
+// boundary. It recurses on the most significant digit at which min and max
+// differ, producing a left fringe (min up to the next round number), a
+// middle block (the fully-covered digits at that position, merged into one
+// digit-class/X pattern rather than emitted one per digit), and a right
+// fringe (down to max).
+func splitNumericRange(min, max string) []string {
+	if min == max {
+		return []string{min}
+	}
+
+	// Find the most significant digit at which min and max differ.
+	i := 0
+	for i < len(min) && min[i] == max[i] {
+		i++
+	}
+	prefix := min[:i]
+	minRest, maxRest := min[i:], max[i:]
+	minDigit, maxDigit := minRest[0], maxRest[0]
+	k := len(minRest) - 1 // digits remaining after this one
+
+	minTail, maxTail := minRest[1:], maxRest[1:]
+	allZeros := strings.Repeat("0", k)
+	allNines := strings.Repeat("9", k)
+
+	// The whole [minDigit, maxDigit] span at this position is fully
+	// covered (min's tail is already all zeros, max's is already all
+	// nines), so it collapses to a single pattern. This also covers the
+	// base case of a single remaining digit, where the tails are both "".
+	if minTail == allZeros && maxTail == allNines {
+		return []string{prefix + digitSpan(minDigit, maxDigit) + strings.Repeat("X", k)}
+	}
+
+	hasLeftFringe := minTail != allZeros
+	hasRightFringe := maxTail != allNines
+
+	middleStart, middleEnd := minDigit, maxDigit
+	var patterns []string
+
+	if hasLeftFringe {
+		for _, s := range splitNumericRange(minTail, allNines) {
+			patterns = append(patterns, prefix+string(minDigit)+s)
+		}
+		middleStart++
+	}
+	if hasRightFringe {
+		middleEnd--
+	}
+	if middleStart <= middleEnd {
+		patterns = append(patterns, prefix+digitSpan(middleStart, middleEnd)+strings.Repeat("X", k))
+	}
+	if hasRightFringe {
+		for _, s := range splitNumericRange(allZeros, maxTail) {
+			patterns = append(patterns, prefix+string(maxDigit)+s)
+		}
+	}
+
+	return patterns
+}
+
+// patternsForEntry pads from/to to equal length and prefixes the result with
+// the entry's country/operator code, so "code,from,to" = "49,100,199"
+// produces the single pattern "491XX".
+func patternsForEntry(e rangeEntry) ([]string, error) {
+	from, to := e.From, e.To
+	for len(from) < len(to) {
+		from = "0" + from
+	}
+	for len(to) < len(from) {
+		to = "0" + to
+	}
+
+	fromN, err := strconv.Atoi(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from %q: %w", e.From, err)
+	}
+	toN, err := strconv.Atoi(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to %q: %w", e.To, err)
+	}
+	if fromN > toN {
+		return nil, fmt.Errorf("range %s-%s is empty (from > to)", e.From, e.To)
+	}
+
+	suffixes := splitNumericRange(from, to)
+	patterns := make([]string, len(suffixes))
+	for i, s := range suffixes {
+		patterns[i] = e.Code + s
+	}
+	return patterns, nil
+}
+
+// loadRangeEntries reads a CSV with header "code,from,to,operator,region".
+func loadRangeEntries(r io.Reader) ([]rangeEntry, error) {
+	reader := csv.NewReader(r)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, required := range []string{"code", "from", "to"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("csv missing required column %q", required)
+		}
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	entries := make([]rangeEntry, 0, len(records)-1)
+	for _, row := range records[1:] {
+		entries = append(entries, rangeEntry{
+			Code:     field(row, "code"),
+			From:     field(row, "from"),
+			To:       field(row, "to"),
+			Operator: field(row, "operator"),
+			Region:   field(row, "region"),
+		})
+	}
+	return entries, nil
+}
+
+// genRangeCmd represents the genRange command
+var genRangeCmd = &cobra.Command{
+	Use:   "genRange [code] [from] [to]",
+	Short: "Expand a prefix number range into Asterisk-style dialplan patterns",
+	Long: `genRange takes a country/operator prefix range (a numeric start-end
+range, optionally under a country/operator code) and emits the minimal set
+of Asterisk-style wildcard patterns that cover it, e.g. "49100X" or
+"491[2-5]X".
+
+Pass a single range directly:
+
+    viterm genRange 49 1000000 1999999
+
+Or load many ranges at once from a national numbering-plan export with
+--file, a CSV with columns code,from,to,operator,region.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if genRangeFile != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(3)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var entries []rangeEntry
+
+		if genRangeFile != "" {
+			f, err := os.Open(genRangeFile)
+			if err != nil {
+				fmt.Println("❌ Could not open file:", err)
+				return
+			}
+			defer f.Close()
+
+			loaded, err := loadRangeEntries(f)
+			if err != nil {
+				fmt.Println("❌ Could not read range CSV:", err)
+				return
+			}
+			entries = loaded
+		} else {
+			entries = []rangeEntry{{
+				Code:     args[0],
+				From:     args[1],
+				To:       args[2],
+				Operator: genRangeOperator,
+				Region:   genRangeRegion,
+			}}
+		}
+
+		if genRangeFilterRegion != "" {
+			entries = filterRangeEntries(entries, func(e rangeEntry) bool {
+				return strings.EqualFold(e.Region, genRangeFilterRegion)
+			})
+		}
+		if genRangeFilterOperator != "" {
+			entries = filterRangeEntries(entries, func(e rangeEntry) bool {
+				return strings.EqualFold(e.Operator, genRangeFilterOperator)
+			})
+		}
+
+		if genRangeGroup {
+			sort.SliceStable(entries, func(i, j int) bool {
+				return entries[i].Operator < entries[j].Operator
+			})
+		}
+
+		for _, e := range entries {
+			patterns, err := patternsForEntry(e)
+			if err != nil {
+				fmt.Printf("❌ %s-%s: %v\n", e.From, e.To, err)
+				continue
+			}
+
+			if genRangeComment {
+				fmt.Printf("; %s-%s %s\n", e.From, e.To, e.Operator)
+			}
+			for _, p := range patterns {
+				fmt.Println(p)
+			}
+		}
+	},
+}
+
+// filterRangeEntries returns the entries for which keep returns true.
+func filterRangeEntries(entries []rangeEntry, keep func(rangeEntry) bool) []rangeEntry {
+	filtered := entries[:0]
+	for _, e := range entries {
+		if keep(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+var (
+	genRangeFile           string
+	genRangeOperator       string
+	genRangeRegion         string
+	genRangeFilterRegion   string
+	genRangeFilterOperator string
+	genRangeComment        bool
+	genRangeGroup          bool
+)
+
+func init() {
+	genRangeCmd.Flags().StringVar(&genRangeFile, "file", "", "load ranges from a CSV file with columns code,from,to,operator,region")
+	genRangeCmd.Flags().StringVar(&genRangeOperator, "operator", "", "operator label for a single range passed on the command line")
+	genRangeCmd.Flags().StringVar(&genRangeRegion, "region", "", "region label for a single range passed on the command line")
+	genRangeCmd.Flags().StringVar(&genRangeFilterRegion, "filter-region", "", "only emit ranges whose region matches (case-insensitive)")
+	genRangeCmd.Flags().StringVar(&genRangeFilterOperator, "filter-operator", "", "only emit ranges whose operator matches (case-insensitive)")
+	genRangeCmd.Flags().BoolVar(&genRangeComment, "comment", false, "emit a \"; from-to operator\" comment above each range's patterns")
+	genRangeCmd.Flags().BoolVar(&genRangeGroup, "group", false, "sort/group output by operator")
+
+	rootCmd.AddCommand(genRangeCmd)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitNumericRangeSingleDigit(t *testing.T) {
+	got := splitNumericRange("3", "7")
+	want := []string{"[3-7]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitNumericRange(3,7) = %v, want %v", got, want)
+	}
+}
+
+func TestSplitNumericRangeExact(t *testing.T) {
+	got := splitNumericRange("1234", "1234")
+	want := []string{"1234"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitNumericRange(1234,1234) = %v, want %v", got, want)
+	}
+}
+
+func TestSplitNumericRangeFullBlock(t *testing.T) {
+	got := splitNumericRange("1000", "1999")
+	want := []string{"1XXX"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitNumericRange(1000,1999) = %v, want %v", got, want)
+	}
+}
+
+func TestSplitNumericRangeFringesAndMiddle(t *testing.T) {
+	got := splitNumericRange("1234", "1567")
+
+	// The result must cover exactly the digits 1234..1567, expressed as
+	// left fringe (1234..1299), middle full block (1[3-4]XX, i.e. 1300..1499),
+	// and right fringe (1500..1567) — verify coverage by expansion instead of
+	// hardcoding the exact pattern set, since the fringe/middle split is an
+	// implementation detail.
+	covered := expandPatterns(got)
+	for n := 1234; n <= 1567; n++ {
+		key := numToPaddedString(n, 4)
+		if !covered[key] {
+			t.Fatalf("splitNumericRange(1234,1567) does not cover %s; patterns: %v", key, got)
+		}
+	}
+	if len(covered) != 1567-1234+1 {
+		t.Errorf("splitNumericRange(1234,1567) covers %d numbers, want %d", len(covered), 1567-1234+1)
+	}
+}
+
+// expandPatterns expands a set of dialplan patterns (digits, "X", and
+// "[a-b]" classes) back into the set of concrete numbers they match.
+func expandPatterns(patterns []string) map[string]bool {
+	covered := make(map[string]bool)
+	for _, p := range patterns {
+		for _, n := range expandPattern(p) {
+			covered[n] = true
+		}
+	}
+	return covered
+}
+
+func expandPattern(p string) []string {
+	results := []string{""}
+	i := 0
+	for i < len(p) {
+		switch {
+		case p[i] == 'X':
+			next := make([]string, 0, len(results)*10)
+			for _, r := range results {
+				for d := '0'; d <= '9'; d++ {
+					next = append(next, r+string(d))
+				}
+			}
+			results = next
+			i++
+		case p[i] == '[':
+			lo, hi := p[i+1], p[i+3]
+			next := make([]string, 0, len(results)*10)
+			for _, r := range results {
+				for d := lo; d <= hi; d++ {
+					next = append(next, r+string(d))
+				}
+			}
+			results = next
+			i += 5 // "[d-d]"
+		default:
+			for j := range results {
+				results[j] += string(p[i])
+			}
+			i++
+		}
+	}
+	return results
+}
+
+func numToPaddedString(n, width int) string {
+	s := ""
+	for n > 0 || s == "" {
+		s = string(rune('0'+n%10)) + s
+		n /= 10
+	}
+	for len(s) < width {
+		s = "0" + s
+	}
+	return s
+}
+
+func TestPatternsForEntry(t *testing.T) {
+	entry := rangeEntry{Code: "49", From: "100", To: "199"}
+	patterns, err := patternsForEntry(entry)
+	if err != nil {
+		t.Fatalf("patternsForEntry: %v", err)
+	}
+	want := []string{"491XX"}
+	if !reflect.DeepEqual(patterns, want) {
+		t.Errorf("patternsForEntry(%+v) = %v, want %v", entry, patterns, want)
+	}
+}
+
+func TestPatternsForEntryInvalidRange(t *testing.T) {
+	entry := rangeEntry{Code: "49", From: "199", To: "100"}
+	if _, err := patternsForEntry(entry); err == nil {
+		t.Fatalf("patternsForEntry(%+v): expected error for from > to, got none", entry)
+	}
+}
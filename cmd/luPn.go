@@ -1,19 +1,72 @@
 package cmd
 
 import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/nyaruka/phonenumbers"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/language"
 	"golang.org/x/text/language/display"
+
+	"github.com/vit0-9/viterm/internal/prefixtree"
+)
+
+// callingCodeTree is a digit trie (one node per digit, not path-compressed)
+// over every E.164 calling code phonenumbers knows about, built once at
+// package init so a prefix lookup walks at most as many digits as the longest
+// code, regardless of how many codes exist.
+var callingCodeTree = buildCallingCodeTree()
+
+func buildCallingCodeTree() *prefixtree.Tree {
+	tree := prefixtree.New()
+	for code := range phonenumbers.GetSupportedCallingCodes() {
+		codeStr := strconv.Itoa(code)
+		tree.Insert(codeStr, codeStr)
+	}
+	return tree
+}
+
+var (
+	countryNameCacheOnce sync.Once
+	countryNameCacheMu   sync.RWMutex
+	countryNameCache     map[string]string
 )
 
 // getCountryName converts an ISO region code (e.g., "DE") to its full English country name (e.g., "Germany").
-// It uses a direct lookup for the region name.
+// Results are cached in-process since batch mode (luPn --file) can look up the
+// same handful of region codes thousands of times over.
 func getCountryName(regionCode string) string {
+	countryNameCacheOnce.Do(func() {
+		countryNameCache = make(map[string]string)
+	})
+
+	countryNameCacheMu.RLock()
+	if name, ok := countryNameCache[regionCode]; ok {
+		countryNameCacheMu.RUnlock()
+		return name
+	}
+	countryNameCacheMu.RUnlock()
+
+	name := resolveCountryName(regionCode)
+
+	countryNameCacheMu.Lock()
+	countryNameCache[regionCode] = name
+	countryNameCacheMu.Unlock()
+
+	return name
+}
+
+// resolveCountryName does the actual ISO region code -> country name lookup.
+func resolveCountryName(regionCode string) string {
 	if regionCode == "" {
 		return "Unknown Region"
 	}
@@ -43,14 +96,175 @@ func getCountryName(regionCode string) string {
 	return name
 }
 
+// numberTypeLabel renders a phonenumbers.PhoneNumberType as a short,
+// human-readable label (e.g. "Mobile", "Toll Free").
+func numberTypeLabel(t phonenumbers.PhoneNumberType) string {
+	switch t {
+	case phonenumbers.FIXED_LINE:
+		return "Fixed Line"
+	case phonenumbers.MOBILE:
+		return "Mobile"
+	case phonenumbers.FIXED_LINE_OR_MOBILE:
+		return "Fixed Line or Mobile"
+	case phonenumbers.TOLL_FREE:
+		return "Toll Free"
+	case phonenumbers.PREMIUM_RATE:
+		return "Premium Rate"
+	case phonenumbers.SHARED_COST:
+		return "Shared Cost"
+	case phonenumbers.VOIP:
+		return "VoIP"
+	case phonenumbers.PERSONAL_NUMBER:
+		return "Personal Number"
+	case phonenumbers.PAGER:
+		return "Pager"
+	case phonenumbers.UAN:
+		return "UAN"
+	case phonenumbers.VOICEMAIL:
+		return "Voicemail"
+	default:
+		return "Unknown"
+	}
+}
+
+// phoneAnalysis is the stable schema emitted for `--format json`, and also
+// backs the human-readable output formats so both stay in sync.
+type phoneAnalysis struct {
+	Input          string   `json:"input"`
+	CountryCode    int      `json:"country_code"`
+	Region         string   `json:"region"`
+	Country        string   `json:"country"`
+	NationalNumber uint64   `json:"national_number"`
+	Valid          bool     `json:"valid"`
+	PossiblyValid  bool     `json:"possibly_valid"`
+	NumberType     string   `json:"number_type"`
+	Carrier        string   `json:"carrier,omitempty"`
+	Timezones      []string `json:"timezones,omitempty"`
+	Location       string   `json:"location,omitempty"`
+	National       string   `json:"national"`
+	International  string   `json:"international"`
+	E164           string   `json:"e164"`
+	RFC3966        string   `json:"rfc3966"`
+}
+
+// buildPhoneAnalysis gathers every field luPn knows how to derive for a
+// successfully parsed number, regardless of which output format was requested.
+func buildPhoneAnalysis(input string, num *phonenumbers.PhoneNumber) phoneAnalysis {
+	region := phonenumbers.GetRegionCodeForNumber(num)
+
+	// Carrier, timezone, and geocoder lookups can fail (e.g. missing
+	// metadata for a region); treat that the same as "nothing to report"
+	// rather than surfacing an error for an enrichment field.
+	carrier, err := phonenumbers.GetCarrierForNumber(num, luPnLang)
+	if err != nil {
+		carrier = ""
+	}
+	timezones, err := phonenumbers.GetTimezonesForNumber(num)
+	if err != nil {
+		timezones = nil
+	}
+	location, err := phonenumbers.GetGeocodingForNumber(num, luPnLang)
+	if err != nil {
+		location = ""
+	}
+
+	return phoneAnalysis{
+		Input:          input,
+		CountryCode:    int(num.GetCountryCode()),
+		Region:         region,
+		Country:        getCountryName(region),
+		NationalNumber: num.GetNationalNumber(),
+		Valid:          phonenumbers.IsValidNumber(num),
+		PossiblyValid:  phonenumbers.IsPossibleNumber(num),
+		NumberType:     numberTypeLabel(phonenumbers.GetNumberType(num)),
+		Carrier:        carrier,
+		Timezones:      timezones,
+		Location:       location,
+		National:       phonenumbers.Format(num, phonenumbers.NATIONAL),
+		International:  phonenumbers.Format(num, phonenumbers.INTERNATIONAL),
+		E164:           phonenumbers.Format(num, phonenumbers.E164),
+		RFC3966:        phonenumbers.Format(num, phonenumbers.RFC3966),
+	}
+}
+
+// printPhoneAnalysis renders an analysis in the requested --format.
+func printPhoneAnalysis(a phoneAnalysis) error {
+	switch luPnFormat {
+	case "national":
+		fmt.Println(a.National)
+	case "international":
+		fmt.Println(a.International)
+	case "e164":
+		fmt.Println(a.E164)
+	case "rfc3966":
+		fmt.Println(a.RFC3966)
+	case "json":
+		out, err := json.MarshalIndent(a, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "all":
+		printPhoneAnalysisDefault(a)
+		fmt.Println("• National:", a.National)
+		fmt.Println("• International:", a.International)
+		fmt.Println("• E.164:", a.E164)
+		fmt.Println("• RFC3966:", a.RFC3966)
+	default:
+		printPhoneAnalysisDefault(a)
+	}
+	return nil
+}
+
+// printPhoneAnalysisDefault renders the original human-readable
+// "Phone Number Analysis" block.
+func printPhoneAnalysisDefault(a phoneAnalysis) {
+	fmt.Println("📞 Phone Number Analysis:")
+	fmt.Printf("• Input: %s\n", a.Input)
+	fmt.Printf("• Country Code: +%d\n", a.CountryCode)
+	fmt.Printf("• Country: %s\n", a.Country)
+	fmt.Printf("• National Number: %d\n", a.NationalNumber)
+	fmt.Printf("• Valid: %t\n", a.Valid)
+	fmt.Printf("• Possibly Valid: %t\n", a.PossiblyValid)
+
+	if a.Carrier != "" {
+		fmt.Printf("• Carrier: %s\n", a.Carrier)
+	}
+
+	if len(a.Timezones) > 0 {
+		fmt.Printf("• Time Zones: %s\n", strings.Join(a.Timezones, ", "))
+	}
+
+	if a.Location != "" {
+		fmt.Printf("• Location: %s\n", a.Location)
+	}
+
+	if luPnNumberType {
+		fmt.Printf("• Number Type: %s\n", a.NumberType)
+	}
+}
+
 // luPnCmd represents the luPn command
 var luPnCmd = &cobra.Command{
 	Use:   "luPn [phone number or prefix]", // Corrected spacing
 	Short: "Look up country info from a phone number or prefix",
 	Long: `luPn (Lookup Phone Number) works with complete numbers like +4912345678
-or country prefixes like +822 to return country information.`,
-	Args: cobra.ExactArgs(1),
+or country prefixes like +822 to return country information.
+
+Pass --file <path> (or --file - for stdin) to look up one number per line in
+bulk instead of a single number.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if luPnFile != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		if luPnFile != "" {
+			runLuPnBatch()
+			return
+		}
+
 		input := args[0]
 
 		// Ensure input starts with "+" for international format parsing.
@@ -62,18 +276,10 @@ or country prefixes like +822 to return country information.`,
 		num, err := phonenumbers.Parse(input, "") // Default region "" means expect international format.
 		if err == nil {
 			// Successfully parsed as a full number.
-			region := phonenumbers.GetRegionCodeForNumber(num)
-			countryName := getCountryName(region)
-			isValid := phonenumbers.IsValidNumber(num)
-			isPossible := phonenumbers.IsPossibleNumber(num)
-
-			fmt.Println("📞 Phone Number Analysis:")
-			fmt.Printf("• Input: %s\n", input)
-			fmt.Printf("• Country Code: +%d\n", num.GetCountryCode())
-			fmt.Printf("• Country: %s\n", countryName)
-			fmt.Printf("• National Number: %d\n", num.GetNationalNumber())
-			fmt.Printf("• Valid: %t\n", isValid)
-			fmt.Printf("• Possibly Valid: %t\n", isPossible)
+			analysis := buildPhoneAnalysis(input, num)
+			if err := printPhoneAnalysis(analysis); err != nil {
+				fmt.Println("❌ Failed to render analysis:", err)
+			}
 			return
 		}
 
@@ -86,47 +292,206 @@ or country prefixes like +822 to return country information.`,
 			return
 		}
 
-		var identifiedPrefixInfo bool
-		// Iterate through possible prefix lengths (max 4 digits, common for country codes, down to 1).
-		for i := min(len(rawInput), 4); i >= 1; i-- {
-			codeCandidateStr := rawInput[:i]
-			codeCandidateInt, convErr := strconv.Atoi(codeCandidateStr)
-			if convErr != nil {
-				// Current candidate substring is not a number; try shorter.
-				continue
-			}
-
-			regions := phonenumbers.GetRegionCodesForCountryCode(codeCandidateInt)
-			if len(regions) > 0 {
-				var countryNames []string
-				for _, region := range regions {
-					countryNames = append(countryNames, getCountryName(region))
-				}
-				fmt.Println("📞 Partial Match (Country Code only):")
-				fmt.Printf("• Input: %s\n", input)
-				fmt.Printf("• Identified Country Code: +%d\n", codeCandidateInt)
-				fmt.Printf("• Possible Countries/Regions: %s\n", strings.Join(countryNames, ", "))
-				identifiedPrefixInfo = true
-				break // Found the longest valid prefix; no need to check shorter ones.
-			}
+		// Longest-prefix-match the input's digits against the calling-code
+		// trie. Unlike a fixed-width scan, this correctly handles calling
+		// codes longer than 4 digits and walks at most as many digits as the
+		// longest code, regardless of how many codes exist.
+		matched, _, remainder, found := callingCodeTree.LongestPrefixMatch(rawInput)
+		if !found {
+			fmt.Println("❌ Could not identify country or region for input:", input)
+			fmt.Println("Hint: Try a valid prefix like +49 or a full number like +4912345678")
+			return
 		}
 
-		if !identifiedPrefixInfo {
+		codeCandidateInt, convErr := strconv.Atoi(matched)
+		if convErr != nil {
 			fmt.Println("❌ Could not identify country or region for input:", input)
 			fmt.Println("Hint: Try a valid prefix like +49 or a full number like +4912345678")
+			return
+		}
+
+		regions := phonenumbers.GetRegionCodesForCountryCode(codeCandidateInt)
+		var countryNames []string
+		for _, region := range regions {
+			countryNames = append(countryNames, getCountryName(region))
+		}
+		fmt.Println("📞 Partial Match (Country Code only):")
+		fmt.Printf("• Input: %s\n", input)
+		fmt.Printf("• Identified Country Code: +%d\n", codeCandidateInt)
+		fmt.Printf("• Possible Countries/Regions: %s\n", strings.Join(countryNames, ", "))
+		if remainder != "" {
+			fmt.Printf("• Remaining Subscriber Digits: %s\n", remainder)
 		}
 	},
 }
 
-// min is a helper function to find the minimum of two integers.
-func min(a, b int) int {
-	if a < b {
-		return a
+// batchResult pairs a line's position in the input with its analysis, so the
+// worker pool below can process lines out of order but still print them back
+// in the order they were read.
+type batchResult struct {
+	index int
+	line  string
+	a     phoneAnalysis
+	err   error
+}
+
+// runLuPnBatch implements `luPn --file <path>`: it reads one phone number per
+// line from a file (or stdin, via "-"), fans the parsing out across a worker
+// pool, and writes one analysis record per line as CSV or NDJSON.
+func runLuPnBatch() {
+	var r io.Reader
+	if luPnFile == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(luPnFile)
+		if err != nil {
+			fmt.Println("❌ Could not open file:", err)
+			return
+		}
+		defer f.Close()
+		r = f
 	}
-	return b
+
+	lines := make(chan struct {
+		index int
+		text  string
+	})
+	results := make(chan batchResult)
+
+	concurrency := luPnConcurrency
+	if concurrency < 1 {
+		concurrency = runtime.NumCPU()
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range lines {
+				results <- analyzeBatchLine(job.index, job.text)
+			}
+		}()
+	}
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		index := 0
+		for scanner.Scan() {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			lines <- struct {
+				index int
+				text  string
+			}{index, text}
+			index++
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintln(os.Stderr, "⚠️  Batch input truncated, stopped reading after line", index, "-", err)
+		}
+	}()
+
+	// Results can arrive out of order; buffer them and print in input order
+	// once every worker has finished.
+	ordered := make(map[int]batchResult)
+	for res := range results {
+		ordered[res.index] = res
+	}
+
+	writeBatchResults(os.Stdout, ordered)
 }
 
+// analyzeBatchLine parses a single line from batch input into a batchResult.
+func analyzeBatchLine(index int, line string) batchResult {
+	input := line
+	if !strings.HasPrefix(input, "+") {
+		input = "+" + input
+	}
+
+	num, err := phonenumbers.Parse(input, "")
+	if err != nil {
+		return batchResult{index: index, line: line, err: err}
+	}
+
+	return batchResult{index: index, line: line, a: buildPhoneAnalysis(input, num)}
+}
+
+// writeBatchResults prints batch analysis records, in input order, as either
+// CSV or NDJSON depending on --output.
+func writeBatchResults(out io.Writer, ordered map[int]batchResult) {
+	switch luPnOutput {
+	case "ndjson":
+		for i := 0; i < len(ordered); i++ {
+			res := ordered[i]
+			if res.err != nil {
+				fmt.Fprintf(out, `{"input":%q,"error":%q}`+"\n", res.line, res.err.Error())
+				continue
+			}
+			encoded, err := json.Marshal(res.a)
+			if err != nil {
+				fmt.Fprintf(out, `{"input":%q,"error":%q}`+"\n", res.line, err.Error())
+				continue
+			}
+			fmt.Fprintln(out, string(encoded))
+		}
+	default: // csv
+		w := csv.NewWriter(out)
+		defer w.Flush()
+		_ = w.Write([]string{"input", "country_code", "region", "country", "national_number", "valid", "possibly_valid", "number_type", "carrier", "timezones", "location", "e164", "error"})
+		for i := 0; i < len(ordered); i++ {
+			res := ordered[i]
+			if res.err != nil {
+				_ = w.Write([]string{res.line, "", "", "", "", "", "", "", "", "", "", "", res.err.Error()})
+				continue
+			}
+			a := res.a
+			_ = w.Write([]string{
+				a.Input,
+				strconv.Itoa(a.CountryCode),
+				a.Region,
+				a.Country,
+				strconv.FormatUint(a.NationalNumber, 10),
+				strconv.FormatBool(a.Valid),
+				strconv.FormatBool(a.PossiblyValid),
+				a.NumberType,
+				a.Carrier,
+				strings.Join(a.Timezones, "|"),
+				a.Location,
+				a.E164,
+				"",
+			})
+		}
+	}
+}
+
+// Flags controlling the display language for carrier/geocoder output,
+// whether to print the detected number type, the output format, and the
+// batch mode input, output format and worker count.
+var (
+	luPnLang        string
+	luPnNumberType  bool
+	luPnFormat      string
+	luPnFile        string
+	luPnOutput      string
+	luPnConcurrency int
+)
+
 func init() {
+	luPnCmd.Flags().StringVar(&luPnLang, "lang", "en", "display language for carrier and location lookups (e.g. en, de, fr)")
+	luPnCmd.Flags().BoolVar(&luPnNumberType, "number-type", false, "also print the detected number type (mobile, fixed-line, VoIP, toll-free, etc.)")
+	luPnCmd.Flags().StringVarP(&luPnFormat, "format", "f", "default", "output format: default, national, international, e164, rfc3966, json, all")
+	luPnCmd.Flags().StringVar(&luPnFile, "file", "", "batch mode: path to a file with one phone number per line (use - for stdin)")
+	luPnCmd.Flags().StringVar(&luPnOutput, "output", "csv", "batch mode output format: csv or ndjson")
+	luPnCmd.Flags().IntVar(&luPnConcurrency, "concurrency", runtime.NumCPU(), "batch mode: number of parallel workers")
+
 	// Assumes rootCmd is defined in another file in the same package (e.g., cmd/root.go)
 	rootCmd.AddCommand(luPnCmd)
 }
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// captureStdout runs f with os.Stdout redirected to a pipe and returns
+// whatever f printed.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+
+	original := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	f()
+
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading captured stdout: %v", err)
+	}
+	return string(out)
+}
+
+func TestNumberTypeLabel(t *testing.T) {
+	cases := []struct {
+		in   phonenumbers.PhoneNumberType
+		want string
+	}{
+		{phonenumbers.FIXED_LINE, "Fixed Line"},
+		{phonenumbers.MOBILE, "Mobile"},
+		{phonenumbers.TOLL_FREE, "Toll Free"},
+		{phonenumbers.VOIP, "VoIP"},
+		{phonenumbers.UNKNOWN, "Unknown"},
+	}
+
+	for _, c := range cases {
+		if got := numberTypeLabel(c.in); got != c.want {
+			t.Errorf("numberTypeLabel(%v) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestBuildPhoneAnalysis(t *testing.T) {
+	input := "+4930123456"
+	num, err := phonenumbers.Parse(input, "")
+	if err != nil {
+		t.Fatalf("phonenumbers.Parse(%q): %v", input, err)
+	}
+
+	a := buildPhoneAnalysis(input, num)
+
+	if a.Input != input {
+		t.Errorf("Input = %q, want %q", a.Input, input)
+	}
+	if a.CountryCode != 49 {
+		t.Errorf("CountryCode = %d, want 49", a.CountryCode)
+	}
+	if a.Region != "DE" {
+		t.Errorf("Region = %q, want %q", a.Region, "DE")
+	}
+	if a.E164 != input {
+		t.Errorf("E164 = %q, want %q", a.E164, input)
+	}
+	if !strings.HasPrefix(a.RFC3966, "tel:") {
+		t.Errorf("RFC3966 = %q, want tel: prefix", a.RFC3966)
+	}
+}
+
+func TestPrintPhoneAnalysisFormats(t *testing.T) {
+	input := "+4930123456"
+	num, err := phonenumbers.Parse(input, "")
+	if err != nil {
+		t.Fatalf("phonenumbers.Parse(%q): %v", input, err)
+	}
+	a := buildPhoneAnalysis(input, num)
+
+	originalFormat := luPnFormat
+	defer func() { luPnFormat = originalFormat }()
+
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{"e164", a.E164},
+		{"national", a.National},
+		{"international", a.International},
+		{"rfc3966", a.RFC3966},
+	}
+
+	for _, c := range cases {
+		luPnFormat = c.format
+		out := captureStdout(t, func() {
+			if err := printPhoneAnalysis(a); err != nil {
+				t.Fatalf("printPhoneAnalysis: %v", err)
+			}
+		})
+		if strings.TrimSpace(out) != c.want {
+			t.Errorf("format %q: output = %q, want %q", c.format, strings.TrimSpace(out), c.want)
+		}
+	}
+
+	luPnFormat = "json"
+	out := captureStdout(t, func() {
+		if err := printPhoneAnalysis(a); err != nil {
+			t.Fatalf("printPhoneAnalysis: %v", err)
+		}
+	})
+	if !strings.Contains(out, `"input": "`+input+`"`) {
+		t.Errorf("json format output missing input field: %q", out)
+	}
+}
+
+func TestAnalyzeBatchLine(t *testing.T) {
+	res := analyzeBatchLine(0, "+4930123456")
+	if res.err != nil {
+		t.Fatalf("analyzeBatchLine: unexpected error %v", res.err)
+	}
+	if res.a.Region != "DE" {
+		t.Errorf("Region = %q, want %q", res.a.Region, "DE")
+	}
+
+	res = analyzeBatchLine(1, "not-a-number")
+	if res.err == nil {
+		t.Fatalf("analyzeBatchLine(%q): expected error, got none", "not-a-number")
+	}
+}
+
+func TestWriteBatchResultsCSV(t *testing.T) {
+	originalOutput := luPnOutput
+	luPnOutput = "csv"
+	defer func() { luPnOutput = originalOutput }()
+
+	ordered := map[int]batchResult{
+		0: analyzeBatchLine(0, "+4930123456"),
+		1: analyzeBatchLine(1, "not-a-number"),
+	}
+
+	var buf bytes.Buffer
+	writeBatchResults(&buf, ordered)
+
+	out := buf.String()
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 3 { // header + 2 records
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), out)
+	}
+	if !strings.HasPrefix(lines[0], "input,country_code,region") {
+		t.Errorf("unexpected CSV header: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "DE") {
+		t.Errorf("row 1 missing region DE: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "not-a-number") {
+		t.Errorf("row 2 missing failed input: %q", lines[2])
+	}
+}
+
+func TestWriteBatchResultsNDJSON(t *testing.T) {
+	originalOutput := luPnOutput
+	luPnOutput = "ndjson"
+	defer func() { luPnOutput = originalOutput }()
+
+	ordered := map[int]batchResult{
+		0: analyzeBatchLine(0, "+4930123456"),
+	}
+
+	var buf bytes.Buffer
+	writeBatchResults(&buf, ordered)
+
+	if !strings.Contains(buf.String(), `"region":"DE"`) {
+		t.Errorf("ndjson output missing region field: %q", buf.String())
+	}
+}
@@ -0,0 +1,70 @@
+// Package prefixtree implements a small 10-ary digit trie over decimal
+// digit strings, used for longest-prefix-match lookups such as matching an
+// E.164 country calling code against an arbitrary-length input.
+package prefixtree
+
+// node is a single digit position in the trie. Children are indexed by
+// digit (0-9) directly rather than through a map, since the alphabet is
+// fixed and tiny. There is one node per digit; single-child chains are not
+// path-compressed.
+type node struct {
+	children [10]*node
+	value    string
+	hasValue bool
+}
+
+// Tree is a digit trie keyed by digit strings.
+type Tree struct {
+	root *node
+}
+
+// New returns an empty Tree.
+func New() *Tree {
+	return &Tree{root: &node{}}
+}
+
+// Insert associates value with key. key must consist of the digits '0'-'9';
+// non-digit keys are ignored.
+func (t *Tree) Insert(key, value string) {
+	n := t.root
+	for i := 0; i < len(key); i++ {
+		d := key[i] - '0'
+		if d > 9 {
+			return
+		}
+		if n.children[d] == nil {
+			n.children[d] = &node{}
+		}
+		n = n.children[d]
+	}
+	n.value = value
+	n.hasValue = true
+}
+
+// LongestPrefixMatch walks input one digit at a time and returns the longest
+// previously-inserted key that is a prefix of input, along with its value
+// and the unmatched remainder of input. ok is false if no inserted key
+// prefixes input at all. Lookup walks at most min(len(input), length of the
+// longest inserted key) digits, independent of how many keys were inserted.
+func (t *Tree) LongestPrefixMatch(input string) (matched, value, remainder string, ok bool) {
+	n := t.root
+	lastMatchLen := -1
+	var lastValue string
+
+	for i := 0; i < len(input); i++ {
+		d := input[i] - '0'
+		if d > 9 || n.children[d] == nil {
+			break
+		}
+		n = n.children[d]
+		if n.hasValue {
+			lastMatchLen = i + 1
+			lastValue = n.value
+		}
+	}
+
+	if lastMatchLen == -1 {
+		return "", "", input, false
+	}
+	return input[:lastMatchLen], lastValue, input[lastMatchLen:], true
+}
@@ -0,0 +1,93 @@
+package prefixtree
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// sampleCallingCodes is a representative slice of real E.164 calling codes,
+// including three- and four-digit codes and codes that nest under a shorter
+// one (7840 under 7), where the longest match must win.
+var sampleCallingCodes = []string{
+	"1", "7", "20", "27", "30", "31", "32", "33", "34", "36",
+	"39", "40", "41", "44", "49", "51", "52", "53", "54", "55",
+	"61", "62", "63", "64", "65", "66", "81", "82", "84", "86",
+	"90", "91", "92", "93", "94", "95", "98",
+	"211", "212", "213", "216", "218", "220", "221", "855", "880",
+	"7840", "7940", // four-digit codes nested under the one-digit code 7
+}
+
+func newSampleTree() *Tree {
+	t := New()
+	for _, code := range sampleCallingCodes {
+		t.Insert(code, code)
+	}
+	return t
+}
+
+func bruteForceMatch(codes []string, input string) (string, bool) {
+	limit := 4
+	if len(input) < limit {
+		limit = len(input)
+	}
+	for i := limit; i >= 1; i-- {
+		candidate := input[:i]
+		for _, code := range codes {
+			if code == candidate {
+				return code, true
+			}
+		}
+	}
+	return "", false
+}
+
+func TestLongestPrefixMatch(t *testing.T) {
+	tree := newSampleTree()
+
+	cases := []struct {
+		input       string
+		wantMatched string
+		wantOK      bool
+	}{
+		{"4912345678", "49", true},
+		{"12025550123", "1", true},
+		{"78401234", "7840", true},
+		{"999999", "", false},
+	}
+
+	for _, c := range cases {
+		matched, _, _, ok := tree.LongestPrefixMatch(c.input)
+		if ok != c.wantOK || matched != c.wantMatched {
+			t.Errorf("LongestPrefixMatch(%q) = (%q, %t), want (%q, %t)", c.input, matched, ok, c.wantMatched, c.wantOK)
+		}
+	}
+}
+
+func randomCorpus(n int) []string {
+	r := rand.New(rand.NewSource(1))
+	corpus := make([]string, n)
+	for i := range corpus {
+		corpus[i] = strconv.Itoa(r.Intn(1_000_000_000))
+	}
+	return corpus
+}
+
+func BenchmarkLongestPrefixMatch(b *testing.B) {
+	tree := newSampleTree()
+	corpus := randomCorpus(100_000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.LongestPrefixMatch(corpus[i%len(corpus)])
+	}
+}
+
+func BenchmarkBruteForceMatch(b *testing.B) {
+	corpus := randomCorpus(100_000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bruteForceMatch(sampleCallingCodes, corpus[i%len(corpus)])
+	}
+}